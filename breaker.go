@@ -0,0 +1,251 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrOpenState is returned by Execute and Allow when the breaker is open
+// and rejecting all requests.
+var ErrOpenState = errors.New("circuitbreaker: circuit breaker is open")
+
+// ErrTooManyRequests is returned by Execute and Allow when the breaker is
+// half-open and has already let through as many requests as
+// maxHalfOpenRequests allows.
+var ErrTooManyRequests = errors.New("circuitbreaker: too many requests")
+
+// Breaker wraps calls to a potentially failing dependency, tripping to
+// StateOpen and rejecting calls once readyToTrip reports enough failures,
+// then probing recovery through StateHalfOpen before returning to
+// StateClosed.
+type Breaker struct {
+	name     string
+	settings settings
+
+	mutex      sync.Mutex
+	state      State
+	generation uint64
+	counts     Counts
+	window     *slidingWindow
+	expiry     time.Time
+}
+
+// NewBreaker creates a Breaker named name, configured by opts. Unset
+// options fall back to defaultSettings. name is not required to be
+// unique; it is only used to identify the breaker to onStateChange.
+// NewBreaker panics if opts produce an invalid settings, e.g.
+// WithSlidingWindow with fewer than 1 bucket.
+func NewBreaker(name string, opts ...SettingsOption) *Breaker {
+	s := defaultSettings
+	for _, opt := range opts {
+		opt.apply(&s)
+	}
+	return newBreakerFromSettings(name, s)
+}
+
+func newBreakerFromSettings(name string, s settings) *Breaker {
+	if err := s.validate(); err != nil {
+		panic(fmt.Sprintf("circuitbreaker: invalid settings: %v", err))
+	}
+
+	cb := &Breaker{
+		name:     name,
+		settings: s,
+	}
+
+	now := s.clock.Now()
+	if s.slidingWindow != nil {
+		cb.window = newSlidingWindow(s.slidingWindow.window, s.slidingWindow.buckets, now)
+	}
+	cb.toNewGeneration(now)
+	return cb
+}
+
+// Name returns the name passed to NewBreaker.
+func (cb *Breaker) Name() string {
+	return cb.name
+}
+
+// State returns the breaker's current state, resolving any pending
+// Open->HalfOpen or Closed reset transition first.
+func (cb *Breaker) State() State {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	state, _ := cb.currentState(cb.settings.clock.Now())
+	return state
+}
+
+// Counts returns a snapshot of the breaker's current counts.
+func (cb *Breaker) Counts() Counts {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	return cb.counts
+}
+
+// Execute runs req if the breaker's state allows it, recording the
+// outcome via settings.isSuccessful and tripping or resetting the breaker
+// as needed. If the breaker is open or has exhausted its half-open
+// allowance, req is not called and ErrOpenState or ErrTooManyRequests is
+// returned instead.
+func (cb *Breaker) Execute(req func() (interface{}, error)) (interface{}, error) {
+	done, err := cb.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := req()
+	done(cb.settings.isSuccessful(err))
+	return result, err
+}
+
+// Allow checks whether a call is currently permitted and, if so, returns
+// a done closure that must be invoked exactly once with the call's
+// eventual outcome. It is the lower-level primitive Execute is built on,
+// meant for streaming RPCs, long-lived WebSocket sessions and other
+// callers whose work doesn't fit a single func() (interface{}, error). A
+// done call that arrives after the breaker has moved on to a new
+// generation — a state transition happened while the call was in flight —
+// is silently ignored rather than corrupting the new generation's counts.
+func (cb *Breaker) Allow() (done func(success bool), err error) {
+	generation, err := cb.beforeRequest()
+	if err != nil {
+		return nil, err
+	}
+	return func(success bool) {
+		cb.afterRequest(generation, success)
+	}, nil
+}
+
+func (cb *Breaker) beforeRequest() (uint64, error) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	now := cb.settings.clock.Now()
+	state, generation := cb.currentState(now)
+
+	maxHalfOpenRequests := cb.settings.maxHalfOpenRequests
+	if maxHalfOpenRequests == 0 {
+		maxHalfOpenRequests = 1
+	}
+
+	switch {
+	case state == StateOpen:
+		return generation, ErrOpenState
+	case state == StateHalfOpen && cb.counts.Requests >= maxHalfOpenRequests:
+		return generation, ErrTooManyRequests
+	}
+
+	cb.counts.onRequest()
+	if cb.window != nil {
+		cb.window.onRequest(now)
+	}
+	return generation, nil
+}
+
+func (cb *Breaker) afterRequest(before uint64, success bool) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	now := cb.settings.clock.Now()
+	state, generation := cb.currentState(now)
+	if generation != before {
+		return
+	}
+
+	if success {
+		cb.onSuccess(state, now)
+	} else {
+		cb.onFailure(state, now)
+	}
+}
+
+func (cb *Breaker) onSuccess(state State, now time.Time) {
+	cb.counts.onSuccess()
+	if cb.window != nil {
+		cb.window.onSuccess(now)
+	}
+	cb.refreshWindowCounts(now)
+
+	if state == StateHalfOpen {
+		cb.setState(StateClosed, now)
+	}
+}
+
+func (cb *Breaker) onFailure(state State, now time.Time) {
+	cb.counts.onFailure()
+	if cb.window != nil {
+		cb.window.onFailure(now)
+	}
+	cb.refreshWindowCounts(now)
+
+	switch state {
+	case StateClosed:
+		if cb.settings.readyToTrip(cb.counts) {
+			cb.setState(StateOpen, now)
+		}
+	case StateHalfOpen:
+		cb.setState(StateOpen, now)
+	}
+}
+
+func (cb *Breaker) refreshWindowCounts(now time.Time) {
+	if cb.window == nil {
+		return
+	}
+	requests, successes, failures := cb.window.counts(now)
+	cb.counts.WindowRequests = requests
+	cb.counts.WindowSuccesses = successes
+	cb.counts.WindowFailures = failures
+}
+
+// currentState resolves a pending timed transition (Closed reset,
+// Open->HalfOpen) against now before returning the state/generation pair
+// readyToTrip and onStateChange should observe.
+func (cb *Breaker) currentState(now time.Time) (State, uint64) {
+	switch cb.state {
+	case StateClosed:
+		if !cb.expiry.IsZero() && cb.expiry.Before(now) {
+			cb.toNewGeneration(now)
+		}
+	case StateOpen:
+		if cb.expiry.Before(now) {
+			cb.setState(StateHalfOpen, now)
+		}
+	}
+	return cb.state, cb.generation
+}
+
+func (cb *Breaker) setState(state State, now time.Time) {
+	if cb.state == state {
+		return
+	}
+
+	prev := cb.state
+	cb.state = state
+	cb.toNewGeneration(now)
+
+	if cb.settings.onStateChange != nil {
+		cb.settings.onStateChange(cb.name, prev, state)
+	}
+}
+
+func (cb *Breaker) toNewGeneration(now time.Time) {
+	cb.generation++
+	cb.counts.reset()
+
+	var zero time.Time
+	switch cb.state {
+	case StateClosed:
+		if cb.settings.closedResetInterval == 0 {
+			cb.expiry = zero
+		} else {
+			cb.expiry = now.Add(cb.settings.closedResetInterval)
+		}
+	case StateOpen:
+		cb.expiry = now.Add(cb.settings.openTimeOut)
+	default: // StateHalfOpen
+		cb.expiry = zero
+	}
+}
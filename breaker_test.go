@@ -0,0 +1,100 @@
+package circuitbreaker_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	circuitbreaker "github.com/zackwwu/circuit-breaker-go"
+	"github.com/zackwwu/circuit-breaker-go/circuitbreakertest"
+)
+
+func TestBreaker_ClosedOpenHalfOpenClosed(t *testing.T) {
+	clock := circuitbreakertest.NewFakeClock(time.Unix(0, 0))
+	var transitions []string
+
+	cb := circuitbreaker.NewBreaker("svc",
+		circuitbreaker.WithClock(clock),
+		circuitbreaker.WithOpenTimeOut(5*time.Second),
+		circuitbreaker.WithReadyToTrip(func(c circuitbreaker.Counts) bool {
+			return c.ConsecutiveFailures >= 2
+		}),
+		circuitbreaker.WithOnStateChange(func(name string, from, to circuitbreaker.State) {
+			transitions = append(transitions, from.String()+"->"+to.String())
+		}),
+	)
+
+	if got := cb.State(); got != circuitbreaker.StateClosed {
+		t.Fatalf("initial state = %v, want %v", got, circuitbreaker.StateClosed)
+	}
+
+	fail := func() {
+		t.Helper()
+		if _, err := cb.Execute(func() (interface{}, error) { return nil, errors.New("boom") }); err == nil {
+			t.Fatalf("Execute returned nil error for a failing call")
+		}
+	}
+
+	fail()
+	if got := cb.State(); got != circuitbreaker.StateClosed {
+		t.Fatalf("state after 1 failure = %v, want %v", got, circuitbreaker.StateClosed)
+	}
+
+	fail()
+	if got := cb.State(); got != circuitbreaker.StateOpen {
+		t.Fatalf("state after 2 consecutive failures = %v, want %v", got, circuitbreaker.StateOpen)
+	}
+
+	if _, err := cb.Execute(func() (interface{}, error) { return "ok", nil }); err != circuitbreaker.ErrOpenState {
+		t.Fatalf("Execute while open returned %v, want %v", err, circuitbreaker.ErrOpenState)
+	}
+
+	clock.Advance(5*time.Second + time.Millisecond)
+	if got := cb.State(); got != circuitbreaker.StateHalfOpen {
+		t.Fatalf("state after openTimeOut elapses = %v, want %v", got, circuitbreaker.StateHalfOpen)
+	}
+
+	if _, err := cb.Execute(func() (interface{}, error) { return "ok", nil }); err != nil {
+		t.Fatalf("Execute while half-open returned %v, want nil", err)
+	}
+	if got := cb.State(); got != circuitbreaker.StateClosed {
+		t.Fatalf("state after a successful half-open call = %v, want %v", got, circuitbreaker.StateClosed)
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if len(transitions) != len(want) {
+		t.Fatalf("transitions = %v, want %v", transitions, want)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Fatalf("transitions = %v, want %v", transitions, want)
+		}
+	}
+}
+
+func TestBreaker_HalfOpenFailureReopens(t *testing.T) {
+	clock := circuitbreakertest.NewFakeClock(time.Unix(0, 0))
+
+	cb := circuitbreaker.NewBreaker("svc",
+		circuitbreaker.WithClock(clock),
+		circuitbreaker.WithOpenTimeOut(time.Second),
+		circuitbreaker.WithReadyToTrip(func(c circuitbreaker.Counts) bool {
+			return c.ConsecutiveFailures >= 1
+		}),
+	)
+
+	cb.Execute(func() (interface{}, error) { return nil, errors.New("boom") })
+	if got := cb.State(); got != circuitbreaker.StateOpen {
+		t.Fatalf("state after tripping = %v, want %v", got, circuitbreaker.StateOpen)
+	}
+
+	clock.Advance(time.Second + time.Millisecond)
+	if got := cb.State(); got != circuitbreaker.StateHalfOpen {
+		t.Fatalf("state after openTimeOut elapses = %v, want %v", got, circuitbreaker.StateHalfOpen)
+	}
+
+	cb.Execute(func() (interface{}, error) { return nil, errors.New("still failing") })
+	if got := cb.State(); got != circuitbreaker.StateOpen {
+		t.Fatalf("state after a half-open failure = %v, want %v", got, circuitbreaker.StateOpen)
+	}
+}
@@ -0,0 +1,35 @@
+// Package circuitbreakertest provides deterministic testing hooks for the
+// circuitbreaker package, principally FakeClock, so that tests can assert
+// Closed->Open->HalfOpen->Closed transitions without time.Sleep.
+package circuitbreakertest
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a circuitbreaker.Clock whose Now only moves when Advance
+// is called. Pass it to a breaker via circuitbreaker.WithClock.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock creates a FakeClock starting at now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the clock's current time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock's time forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,26 @@
+package circuitbreaker
+
+import "time"
+
+// Clock abstracts the passage of time so that state-transition timing
+// (closedResetInterval rollover, openTimeOut expiry, sliding-window bucket
+// advancement) can be driven by something other than the wall clock in
+// tests. See WithClock and the circuitbreakertest subpackage's FakeClock.
+type Clock interface {
+	// Now returns the current time, as time.Now would.
+	Now() time.Time
+}
+
+// realClock is the default Clock, backed by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// WithClock overrides the Clock used for all state-transition timing.
+// Intended for deterministic tests; production callers should leave this
+// unset and get realClock.
+func WithClock(clock Clock) SettingsOption {
+	return SettingsOption(func(s *settings) {
+		s.clock = clock
+	})
+}
@@ -0,0 +1,71 @@
+package circuitbreaker
+
+import "sync"
+
+// Group manages a keyed collection of breakers that all share the same
+// base settings, creating each one lazily the first time it is
+// requested via Get.
+type Group struct {
+	mu                sync.Mutex
+	settings          settings
+	userOnStateChange func(name string, from, to State)
+	extra             []func(name string, from, to State)
+	breakers          map[string]*Breaker
+}
+
+// NewGroup creates a Group whose breakers are configured by opts.
+func NewGroup(opts ...SettingsOption) *Group {
+	s := defaultSettings
+	for _, opt := range opts {
+		opt.apply(&s)
+	}
+
+	g := &Group{breakers: make(map[string]*Breaker)}
+	g.userOnStateChange = s.onStateChange
+	s.onStateChange = g.notifyStateChange
+	g.settings = s
+	return g
+}
+
+// Get returns the breaker named name, creating it on first use.
+func (g *Group) Get(name string) *Breaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if cb, ok := g.breakers[name]; ok {
+		return cb
+	}
+
+	cb := newBreakerFromSettings(name, g.settings)
+	g.breakers[name] = cb
+	return cb
+}
+
+// OnStateChange registers fn to run, in addition to any onStateChange set
+// via WithOnStateChange, whenever any breaker in the group changes state
+// — including breakers already returned by an earlier Get, since every
+// breaker's onStateChange forwards into the group rather than a snapshot
+// of fn taken at construction time. This is how the metrics subpackage
+// wires a single Prometheus exporter across every breaker in the group.
+func (g *Group) OnStateChange(fn func(name string, from, to State)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.extra = append(g.extra, fn)
+}
+
+// notifyStateChange is installed as every breaker's onStateChange; it
+// looks up the current callbacks at call time rather than baking in
+// whatever was registered when the breaker was created.
+func (g *Group) notifyStateChange(name string, from, to State) {
+	g.mu.Lock()
+	userOnStateChange := g.userOnStateChange
+	extra := append([]func(string, State, State){}, g.extra...)
+	g.mu.Unlock()
+
+	if userOnStateChange != nil {
+		userOnStateChange(name, from, to)
+	}
+	for _, fn := range extra {
+		fn(name, from, to)
+	}
+}
@@ -0,0 +1,95 @@
+// Package grpcbreaker provides gRPC client interceptors that route calls
+// through a circuitbreaker.Breaker.
+package grpcbreaker
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	circuitbreaker "github.com/zackwwu/circuit-breaker-go"
+)
+
+// errClassifiedFailure is recorded against the breaker when isSuccessful
+// reports failure for a call that itself returned a nil error; it never
+// leaks to the caller.
+var errClassifiedFailure = errors.New("grpcbreaker: call classified as failure")
+
+// IsSuccessful classifies the outcome of a single RPC for the purposes of
+// the wrapping breaker.
+type IsSuccessful func(err error) bool
+
+// DefaultIsSuccessful treats codes.Unavailable and codes.DeadlineExceeded
+// as failures and every other outcome, including nil, as a success.
+func DefaultIsSuccessful(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return false
+	default:
+		return true
+	}
+}
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor that routes
+// calls through cb, using isSuccessful (or DefaultIsSuccessful if nil) to
+// classify the outcome. The breaker's own rejection error
+// (circuitbreaker.ErrOpenState or circuitbreaker.ErrTooManyRequests) is
+// returned in place of calling the RPC; otherwise the RPC's own error is
+// returned unchanged.
+func UnaryClientInterceptor(cb *circuitbreaker.Breaker, isSuccessful IsSuccessful) grpc.UnaryClientInterceptor {
+	if isSuccessful == nil {
+		isSuccessful = DefaultIsSuccessful
+	}
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var callErr error
+		_, execErr := cb.Execute(func() (interface{}, error) {
+			callErr = invoker(ctx, method, req, reply, cc, opts...)
+			if isSuccessful(callErr) {
+				return nil, nil
+			}
+			if callErr != nil {
+				return nil, callErr
+			}
+			return nil, errClassifiedFailure
+		})
+
+		if execErr == circuitbreaker.ErrOpenState || execErr == circuitbreaker.ErrTooManyRequests {
+			return execErr
+		}
+		return callErr
+	}
+}
+
+// StreamClientInterceptor returns a grpc.StreamClientInterceptor that
+// routes stream creation through cb. Only the initial stream setup is
+// breaker-gated; success or failure of messages exchanged on an
+// already-open stream is the caller's concern.
+func StreamClientInterceptor(cb *circuitbreaker.Breaker, isSuccessful IsSuccessful) grpc.StreamClientInterceptor {
+	if isSuccessful == nil {
+		isSuccessful = DefaultIsSuccessful
+	}
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		var (
+			stream  grpc.ClientStream
+			callErr error
+		)
+		_, execErr := cb.Execute(func() (interface{}, error) {
+			stream, callErr = streamer(ctx, desc, cc, method, opts...)
+			if isSuccessful(callErr) {
+				return nil, nil
+			}
+			if callErr != nil {
+				return nil, callErr
+			}
+			return nil, errClassifiedFailure
+		})
+
+		if execErr == circuitbreaker.ErrOpenState || execErr == circuitbreaker.ErrTooManyRequests {
+			return nil, execErr
+		}
+		return stream, callErr
+	}
+}
@@ -0,0 +1,153 @@
+// Package httpbreaker wraps an http.RoundTripper with a circuitbreaker.Breaker.
+package httpbreaker
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	circuitbreaker "github.com/zackwwu/circuit-breaker-go"
+)
+
+// errClassifiedFailure is recorded against the breaker when isSuccessful
+// reports failure for a round trip that itself returned a nil error; it
+// never leaks to the caller.
+var errClassifiedFailure = errors.New("httpbreaker: response classified as failure")
+
+// IsSuccessful classifies the outcome of a single round trip for the
+// purposes of the wrapping breaker.
+type IsSuccessful func(resp *http.Response, err error) bool
+
+// DefaultIsSuccessful treats a transport error or a 5xx response as a
+// failure and everything else, including 4xx responses, as a success.
+func DefaultIsSuccessful(resp *http.Response, err error) bool {
+	if err != nil {
+		return false
+	}
+	return resp.StatusCode < 500
+}
+
+// Option configures a Transport.
+type Option func(*options)
+
+type options struct {
+	isSuccessful IsSuccessful
+}
+
+// WithIsSuccessful overrides the classification used to decide whether a
+// round trip counts as a success or failure. The default is
+// DefaultIsSuccessful.
+func WithIsSuccessful(fn IsSuccessful) Option {
+	return func(o *options) {
+		o.isSuccessful = fn
+	}
+}
+
+// Transport wraps base with cb, rejecting requests with cb's own error
+// (circuitbreaker.ErrOpenState or circuitbreaker.ErrTooManyRequests) while
+// the breaker is open or its half-open allowance is exhausted.
+type Transport struct {
+	base         http.RoundTripper
+	cb           *circuitbreaker.Breaker
+	isSuccessful IsSuccessful
+}
+
+// NewTransport wraps base with cb. If base is nil, http.DefaultTransport
+// is used.
+func NewTransport(base http.RoundTripper, cb *circuitbreaker.Breaker, opts ...Option) http.RoundTripper {
+	o := options{isSuccessful: DefaultIsSuccessful}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{base: base, cb: cb, isSuccessful: o.isSuccessful}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return roundTrip(t.cb, t.base, t.isSuccessful, req)
+}
+
+// roundTrip executes req through base, reporting the outcome to cb via
+// isSuccessful. The breaker's own accounting error (errClassifiedFailure,
+// synthesized when isSuccessful rejects a round trip that itself returned
+// a nil error) is only ever fed to cb.Execute; the caller always gets
+// back the real resp/err pair base.RoundTrip produced, or cb's own
+// rejection error, never a synthesized one — returning a non-nil error
+// alongside a non-nil resp would violate the http.RoundTripper contract.
+func roundTrip(cb *circuitbreaker.Breaker, base http.RoundTripper, isSuccessful IsSuccessful, req *http.Request) (*http.Response, error) {
+	type outcome struct {
+		resp *http.Response
+		err  error
+	}
+
+	v, execErr := cb.Execute(func() (interface{}, error) {
+		resp, err := base.RoundTrip(req)
+		o := outcome{resp, err}
+		if isSuccessful(resp, err) {
+			return o, nil
+		}
+		if err != nil {
+			return o, err
+		}
+		return o, errClassifiedFailure
+	})
+
+	if execErr == circuitbreaker.ErrOpenState || execErr == circuitbreaker.ErrTooManyRequests {
+		return nil, execErr
+	}
+
+	o := v.(outcome)
+	return o.resp, o.err
+}
+
+// Group manages a keyed set of breakers, lazily creating one per host the
+// first time NewPerHostTransport sees it.
+type Group struct {
+	mu       sync.Mutex
+	breakers map[string]*circuitbreaker.Breaker
+	opts     []circuitbreaker.SettingsOption
+}
+
+// NewGroup creates a Group whose per-host breakers are configured by opts.
+func NewGroup(opts ...circuitbreaker.SettingsOption) *Group {
+	return &Group{breakers: make(map[string]*circuitbreaker.Breaker), opts: opts}
+}
+
+func (g *Group) breakerFor(host string) *circuitbreaker.Breaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	cb, ok := g.breakers[host]
+	if !ok {
+		cb = circuitbreaker.NewBreaker(host, g.opts...)
+		g.breakers[host] = cb
+	}
+	return cb
+}
+
+// NewPerHostTransport wraps base so that each request is routed through
+// the breaker for req.URL.Host, created lazily from g. If base is nil,
+// http.DefaultTransport is used.
+func (g *Group) NewPerHostTransport(base http.RoundTripper, opts ...Option) http.RoundTripper {
+	o := options{isSuccessful: DefaultIsSuccessful}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &groupTransport{base: base, group: g, isSuccessful: o.isSuccessful}
+}
+
+type groupTransport struct {
+	base         http.RoundTripper
+	group        *Group
+	isSuccessful IsSuccessful
+}
+
+func (t *groupTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cb := t.group.breakerFor(req.URL.Host)
+	return roundTrip(cb, t.base, t.isSuccessful, req)
+}
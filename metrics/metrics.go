@@ -0,0 +1,118 @@
+// Package metrics exports Prometheus metrics for every breaker in a
+// circuitbreaker.Group.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	circuitbreaker "github.com/zackwwu/circuit-breaker-go"
+)
+
+// Collector exposes, per breaker name: a gauge for the current state
+// (0=closed, 1=half-open, 2=open), counters for requests/successes/
+// failures/rejections, and a histogram of time spent in each state before
+// transitioning out of it. The gauge and the histogram are only updated on
+// state changes: a breaker that has never changed state has no gauge
+// value, and the time a breaker spends in its first state is not observed.
+type Collector struct {
+	state       *prometheus.GaugeVec
+	requests    *prometheus.CounterVec
+	successes   *prometheus.CounterVec
+	failures    *prometheus.CounterVec
+	rejections  *prometheus.CounterVec
+	timeInState *prometheus.HistogramVec
+
+	mu        sync.Mutex
+	enteredAt map[string]time.Time
+}
+
+// NewCollector creates a Collector and registers its metrics with reg.
+func NewCollector(reg prometheus.Registerer) *Collector {
+	c := &Collector{
+		state: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "circuitbreaker_state",
+			Help: "Current state of the breaker (0=closed, 1=half-open, 2=open).",
+		}, []string{"name"}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "circuitbreaker_requests_total",
+			Help: "Total requests let through the breaker.",
+		}, []string{"name"}),
+		successes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "circuitbreaker_successes_total",
+			Help: "Total requests recorded as successful.",
+		}, []string{"name"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "circuitbreaker_failures_total",
+			Help: "Total requests recorded as failed.",
+		}, []string{"name"}),
+		rejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "circuitbreaker_rejections_total",
+			Help: "Total requests rejected while open or half-open.",
+		}, []string{"name"}),
+		timeInState: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "circuitbreaker_time_in_state_seconds",
+			Help: "Time spent in a state before transitioning out of it.",
+		}, []string{"name", "state"}),
+		enteredAt: make(map[string]time.Time),
+	}
+	reg.MustRegister(c.state, c.requests, c.successes, c.failures, c.rejections, c.timeInState)
+	return c
+}
+
+// Register wires c into every breaker in g, present and future, via
+// Group.OnStateChange.
+func (c *Collector) Register(g *circuitbreaker.Group) {
+	g.OnStateChange(c.onStateChange)
+}
+
+func (c *Collector) onStateChange(name string, from, to circuitbreaker.State) {
+	c.state.WithLabelValues(name).Set(float64(to))
+
+	c.mu.Lock()
+	enteredAt, hadPrevious := c.enteredAt[name]
+	c.enteredAt[name] = time.Now()
+	c.mu.Unlock()
+
+	if hadPrevious {
+		c.timeInState.WithLabelValues(name, from.String()).Observe(time.Since(enteredAt).Seconds())
+	}
+}
+
+// Observe records the outcome of a single call made against name's
+// breaker. State changes are picked up automatically via Register, but
+// per-call request/success/failure/rejection counts aren't visible from a
+// state-change callback alone, so callers report them explicitly.
+//
+// err is only consulted to detect the breaker's own rejection
+// (circuitbreaker.ErrOpenState / circuitbreaker.ErrTooManyRequests);
+// success must be the breaker's own classification of the call, not
+// err == nil. Those two diverge for wrappers like httpbreaker and
+// grpcbreaker, which deliberately return a nil error to the caller for a
+// call they still classify (and count) as a failure — e.g. a 5xx HTTP
+// response under the http.RoundTripper contract — so rederiving success
+// from err here would silently disagree with the breaker's own counts.
+//
+//	resp, err := transport.RoundTrip(req)
+//	collector.Observe(name, err, httpbreaker.DefaultIsSuccessful(resp, err))
+//
+// For a plain circuitbreaker.Breaker with the default isSuccessful, err
+// itself is the classification:
+//
+//	_, err := cb.Execute(doWork)
+//	collector.Observe(name, err, err == nil)
+func (c *Collector) Observe(name string, err error, success bool) {
+	if err == circuitbreaker.ErrOpenState || err == circuitbreaker.ErrTooManyRequests {
+		c.rejections.WithLabelValues(name).Inc()
+		return
+	}
+
+	c.requests.WithLabelValues(name).Inc()
+	if success {
+		c.successes.WithLabelValues(name).Inc()
+	} else {
+		c.failures.WithLabelValues(name).Inc()
+	}
+}
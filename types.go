@@ -28,51 +28,87 @@ func (s State) String() string {
 	}
 }
 
-type counts struct {
-	requests             uint32
-	totalSuccesses       uint32
-	totalFailures        uint32
-	consecutiveSuccesses uint32
-	consecutiveFailures  uint32
+// Counts holds the request/success/failure counts observed by a Breaker.
+// ConsecutiveSuccesses and ConsecutiveFailures reset to 0 whenever the
+// other kind of outcome is observed. WindowRequests, WindowSuccesses and
+// WindowFailures are only populated when the breaker is configured with
+// WithSlidingWindow; they report the counts observed in the trailing
+// window rather than since the last reset.
+type Counts struct {
+	Requests             uint32
+	TotalSuccesses       uint32
+	TotalFailures        uint32
+	ConsecutiveSuccesses uint32
+	ConsecutiveFailures  uint32
+
+	WindowRequests  uint32
+	WindowSuccesses uint32
+	WindowFailures  uint32
+}
+
+// FailureRate returns WindowFailures divided by WindowRequests, or 0 if no
+// requests have landed in the window yet. It is only meaningful when the
+// breaker is configured with WithSlidingWindow.
+func (c Counts) FailureRate() float64 {
+	if c.WindowRequests == 0 {
+		return 0
+	}
+	return float64(c.WindowFailures) / float64(c.WindowRequests)
+}
+
+func (c *Counts) onRequest() {
+	c.Requests++
 }
 
-func (c *counts) onRequest() {
-	c.requests++
+func (c *Counts) onSuccess() {
+	c.TotalSuccesses++
+	c.ConsecutiveSuccesses++
+	c.ConsecutiveFailures = 0
 }
 
-func (c *counts) onSuccess() {
-	c.totalSuccesses++
-	c.consecutiveSuccesses++
-	c.consecutiveFailures = 0
+func (c *Counts) onFailure() {
+	c.TotalFailures++
+	c.ConsecutiveFailures++
+	c.ConsecutiveSuccesses = 0
 }
 
-func (c *counts) onFailure() {
-	c.totalFailures++
-	c.consecutiveFailures++
-	c.consecutiveSuccesses = 0
+func (c *Counts) reset() {
+	*c = Counts{}
 }
 
-func (c *counts) reset() {
-	c.requests = 0
-	c.totalSuccesses = 0
-	c.totalFailures = 0
-	c.consecutiveSuccesses = 0
-	c.consecutiveFailures = 0
+// slidingWindowSettings configures the bucketed sliding window maintained
+// alongside the monotonic Counts. See WithSlidingWindow.
+type slidingWindowSettings struct {
+	window  time.Duration
+	buckets int
 }
 
 type settings struct {
 	maxHalfOpenRequests uint32
 	closedResetInterval time.Duration
 	openTimeOut         time.Duration
-	readyToTrip         func(counts counts) bool
+	readyToTrip         func(counts Counts) bool
 	onStateChange       func(name string, from State, to State)
 	isSuccessful        func(err error) bool
-}
-
-func (s settings) validate() error {
-	return validation.ValidateStruct(&s,
-		validation.Field(s.closedResetInterval, validation.Min(time.Duration(1))),
-		validation.Field(s.openTimeOut, validation.Min(time.Duration(1))),
+	slidingWindow       *slidingWindowSettings
+	clock               Clock
+}
+
+func (s *settings) validate() error {
+	if w := s.slidingWindow; w != nil {
+		if w.buckets < 1 {
+			return fmt.Errorf("circuitbreaker: sliding window buckets must be at least 1, got %d", w.buckets)
+		}
+		if w.window <= 0 {
+			return fmt.Errorf("circuitbreaker: sliding window duration must be positive, got %s", w.window)
+		}
+		if w.window/time.Duration(w.buckets) <= 0 {
+			return fmt.Errorf("circuitbreaker: sliding window of %s split into %d buckets truncates to a zero-length bucket; use a larger window or fewer buckets", w.window, w.buckets)
+		}
+	}
+	return validation.ValidateStruct(s,
+		validation.Field(&s.closedResetInterval, validation.Min(time.Duration(1))),
+		validation.Field(&s.openTimeOut, validation.Min(time.Duration(1))),
 	)
 }
 
@@ -108,7 +144,7 @@ func WithOpenTimeOut(timeout time.Duration) SettingsOption {
 
 // WithReadyToTrip sets the function to determine whether the circuit breaker should
 // transition from the closed state to the open state.
-func WithReadyToTrip(fn func(counts counts) bool) SettingsOption {
+func WithReadyToTrip(fn func(counts Counts) bool) SettingsOption {
 	return SettingsOption(func(s *settings) {
 		s.readyToTrip = fn
 	})
@@ -128,8 +164,22 @@ func WithIsSuccessful(fn func(err error) bool) SettingsOption {
 	})
 }
 
-func defaultReadyToTrip(counts counts) bool {
-	return counts.consecutiveFailures > 5
+// WithSlidingWindow configures the breaker to additionally track
+// requests/successes/failures over a rolling window of the given
+// duration, split into the given number of buckets. readyToTrip can then
+// inspect Counts.WindowRequests, Counts.WindowFailures and
+// Counts.FailureRate to trip on a recent failure rate instead of, or in
+// addition to, ConsecutiveFailures. buckets must be at least 1; the
+// window advances one bucket at a time as the breaker's Clock moves past a bucket's
+// span, with expired buckets zeroed lazily rather than on a timer.
+func WithSlidingWindow(window time.Duration, buckets int) SettingsOption {
+	return SettingsOption(func(s *settings) {
+		s.slidingWindow = &slidingWindowSettings{window: window, buckets: buckets}
+	})
+}
+
+func defaultReadyToTrip(counts Counts) bool {
+	return counts.ConsecutiveFailures > 5
 }
 
 func defaultIsSuccessful(err error) bool {
@@ -143,4 +193,5 @@ var defaultSettings = settings{
 	readyToTrip:         defaultReadyToTrip,
 	onStateChange:       nil,
 	isSuccessful:        defaultIsSuccessful,
+	clock:               realClock{},
 }
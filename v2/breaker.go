@@ -0,0 +1,69 @@
+// Package v2 provides a generic, typed wrapper around circuitbreaker.Breaker
+// for callers on Go 1.18+, mirroring the gobreaker/v2 redesign. It avoids
+// the interface{} boxing the base Execute requires and additionally
+// recovers panics raised inside the wrapped function, counting them as
+// failures before re-panicking.
+package v2
+
+import (
+	"errors"
+
+	circuitbreaker "github.com/zackwwu/circuit-breaker-go"
+)
+
+// errPanicked is recorded against the underlying breaker when req panics,
+// so that the panic still counts as a failure for trip accounting before
+// Execute re-panics to the caller.
+var errPanicked = errors.New("circuitbreaker: panic recovered")
+
+// Breaker is a typed wrapper around a circuitbreaker.Breaker. Its zero
+// value is not usable; construct one with NewBreaker.
+type Breaker[T any] struct {
+	cb *circuitbreaker.Breaker
+}
+
+// NewBreaker creates a typed Breaker named name, configured by opts. opts
+// are the same circuitbreaker.SettingsOption used by the base API.
+func NewBreaker[T any](name string, opts ...circuitbreaker.SettingsOption) *Breaker[T] {
+	return &Breaker[T]{cb: circuitbreaker.NewBreaker(name, opts...)}
+}
+
+// Execute runs req if the breaker's state allows it, returning
+// circuitbreaker.ErrOpenState or circuitbreaker.ErrTooManyRequests
+// without calling req otherwise. A panic inside req is recovered, counted
+// as a failure, and then re-panicked once the breaker's state has been
+// updated.
+func (b *Breaker[T]) Execute(req func() (T, error)) (T, error) {
+	var panicked any
+
+	result, err := b.cb.Execute(func() (result interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				panicked = r
+				err = errPanicked
+			}
+		}()
+		return req()
+	})
+
+	if panicked != nil {
+		panic(panicked)
+	}
+
+	var zero T
+	if err != nil {
+		return zero, err
+	}
+	v, _ := result.(T)
+	return v, nil
+}
+
+// Name returns the name passed to NewBreaker.
+func (b *Breaker[T]) Name() string {
+	return b.cb.Name()
+}
+
+// State returns the breaker's current state.
+func (b *Breaker[T]) State() circuitbreaker.State {
+	return b.cb.State()
+}
@@ -0,0 +1,83 @@
+package circuitbreaker
+
+import "time"
+
+// bucket accumulates the requests/successes/failures observed during a
+// single slice of a slidingWindow. expiresAt is the time at which the
+// bucket's contents stop applying and must be zeroed before reuse.
+type bucket struct {
+	requests  uint32
+	successes uint32
+	failures  uint32
+	expiresAt time.Time
+}
+
+// slidingWindow is a ring of fixed-width time buckets used to answer
+// "how many requests/failures happened in roughly the last N seconds"
+// without retaining per-request timestamps. Buckets are reused in place;
+// a bucket whose expiresAt has passed is treated as empty and zeroed the
+// next time it is written to, so advancing the window costs nothing until
+// it is actually touched again. Bucket index is derived from elapsed time
+// since start rather than from the clock's raw value, so it works with
+// clocks set before the Unix epoch (e.g. a FakeClock started at the zero
+// time.Time) instead of risking a negative index.
+type slidingWindow struct {
+	start     time.Time
+	bucketLen time.Duration
+	buckets   []bucket
+}
+
+func newSlidingWindow(window time.Duration, numBuckets int, start time.Time) *slidingWindow {
+	return &slidingWindow{
+		start:     start,
+		bucketLen: window / time.Duration(numBuckets),
+		buckets:   make([]bucket, numBuckets),
+	}
+}
+
+// currentBucket returns the bucket now falls into, resetting it first if
+// its previous contents have expired.
+func (w *slidingWindow) currentBucket(now time.Time) *bucket {
+	elapsed := now.Sub(w.start)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	bucketsElapsed := elapsed / w.bucketLen
+	idx := int(bucketsElapsed) % len(w.buckets)
+	b := &w.buckets[idx]
+	// A bucket stays in the window until the ring has wrapped all the way
+	// round to it again.
+	expiresAt := w.start.Add((bucketsElapsed + time.Duration(len(w.buckets))) * w.bucketLen)
+	if !now.Before(b.expiresAt) {
+		*b = bucket{expiresAt: expiresAt}
+	}
+	return b
+}
+
+func (w *slidingWindow) onRequest(now time.Time) {
+	w.currentBucket(now).requests++
+}
+
+func (w *slidingWindow) onSuccess(now time.Time) {
+	w.currentBucket(now).successes++
+}
+
+func (w *slidingWindow) onFailure(now time.Time) {
+	w.currentBucket(now).failures++
+}
+
+// counts aggregates every non-expired bucket as of now. Expired buckets
+// are skipped rather than mutated, so calling counts does not itself
+// advance the window.
+func (w *slidingWindow) counts(now time.Time) (requests, successes, failures uint32) {
+	for i := range w.buckets {
+		b := &w.buckets[i]
+		if !now.Before(b.expiresAt) {
+			continue
+		}
+		requests += b.requests
+		successes += b.successes
+		failures += b.failures
+	}
+	return requests, successes, failures
+}
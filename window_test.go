@@ -0,0 +1,85 @@
+package circuitbreaker_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	circuitbreaker "github.com/zackwwu/circuit-breaker-go"
+	"github.com/zackwwu/circuit-breaker-go/circuitbreakertest"
+)
+
+func TestNewBreaker_InvalidSlidingWindowPanics(t *testing.T) {
+	cases := []struct {
+		name    string
+		window  time.Duration
+		buckets int
+	}{
+		{"zero buckets", time.Second, 0},
+		{"negative buckets", time.Second, -1},
+		{"zero window", 0, 3},
+		{"negative window", -time.Second, 3},
+		{"window truncates to a zero-length bucket", time.Nanosecond, 10},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Fatalf("NewBreaker did not panic for window=%s buckets=%d", tc.window, tc.buckets)
+				}
+			}()
+			circuitbreaker.NewBreaker("x", circuitbreaker.WithSlidingWindow(tc.window, tc.buckets))
+		})
+	}
+}
+
+func TestSlidingWindow_CountsBeforeUnixEpoch(t *testing.T) {
+	// time.Time{} is year 1, long before the Unix epoch; bucket indexing
+	// must not go negative when the clock is started there.
+	clock := circuitbreakertest.NewFakeClock(time.Time{})
+
+	cb := circuitbreaker.NewBreaker("x",
+		circuitbreaker.WithClock(clock),
+		circuitbreaker.WithSlidingWindow(10*time.Second, 10),
+	)
+
+	for i := 0; i < 3; i++ {
+		cb.Execute(func() (interface{}, error) { return nil, errors.New("boom") })
+	}
+	cb.Execute(func() (interface{}, error) { return "ok", nil })
+
+	counts := cb.Counts()
+	if counts.WindowRequests != 4 {
+		t.Fatalf("WindowRequests = %d, want 4", counts.WindowRequests)
+	}
+	if counts.WindowFailures != 3 {
+		t.Fatalf("WindowFailures = %d, want 3", counts.WindowFailures)
+	}
+	if counts.WindowSuccesses != 1 {
+		t.Fatalf("WindowSuccesses = %d, want 1", counts.WindowSuccesses)
+	}
+}
+
+func TestSlidingWindow_ExpiredBucketsDropOut(t *testing.T) {
+	clock := circuitbreakertest.NewFakeClock(time.Unix(0, 0))
+
+	cb := circuitbreaker.NewBreaker("x",
+		circuitbreaker.WithClock(clock),
+		circuitbreaker.WithSlidingWindow(10*time.Second, 10),
+	)
+
+	cb.Execute(func() (interface{}, error) { return nil, errors.New("boom") })
+	if got := cb.Counts().WindowFailures; got != 1 {
+		t.Fatalf("WindowFailures immediately after a failure = %d, want 1", got)
+	}
+
+	// Window counts are recomputed as of the next recorded outcome, not on
+	// a background timer, so advance the clock past the whole window and
+	// then make one more call to force a refresh.
+	clock.Advance(10 * time.Second)
+	cb.Execute(func() (interface{}, error) { return "ok", nil })
+	if got := cb.Counts().WindowFailures; got != 0 {
+		t.Fatalf("WindowFailures after the window fully elapses = %d, want 0", got)
+	}
+}